@@ -0,0 +1,179 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// inputExtensions maps each input format to the file extension of the
+// archive entries that are converted
+var inputExtensions = map[string]string{
+	formatJSON:    ".json",
+	formatJSONSeq: ".json-seq",
+}
+
+// outputExtensions maps each output format to the file extension used for
+// converted archive entries
+var outputExtensions = map[string]string{
+	formatYAML:     ".yaml",
+	formatPointers: ".txt",
+	formatHTML:     ".html",
+}
+
+// parseSince parses the value given to --since: either a duration before
+// now such as 24h, or an RFC 3339 timestamp or date
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("duration must not be negative, got %s", value)
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("expected a duration (e.g. 24h) or a timestamp (e.g. 2006-01-02 or 2006-01-02T15:04:05Z), got %q", value)
+}
+
+// validateSince checks the value given to --since
+func validateSince(value string) error {
+	_, err := parseSince(value, time.Now())
+	return err
+}
+
+// convertArchive reads a tar archive from r, converts every input entry and
+// writes the resulting tar archive to w. A gzip-compressed input is detected
+// automatically and the output is compressed the same way. Other entries
+// are copied unchanged. Input entries modified before since are left out
+// of the output and counted as skipped; a zero since converts everything.
+func convertArchive(r io.Reader, w io.Writer, opts ConvertOptions, since time.Time) (converted int, skipped int, err error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(2)
+	compressed := bytes.Equal(magic, []byte{0x1f, 0x8b})
+
+	var in io.Reader = br
+	if compressed {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to read gzip stream: %w", err)
+		}
+		defer gr.Close()
+		in = gr
+	}
+
+	out := w
+	var gw *gzip.Writer
+	if compressed {
+		gw = gzip.NewWriter(w)
+		out = gw
+	}
+
+	tr := tar.NewReader(in)
+	tw := tar.NewWriter(out)
+	inputExt := inputExtensions[cmp.Or(opts.From, formatJSON)]
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return converted, skipped, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		if hdr.Typeflag != tar.TypeReg || !strings.EqualFold(path.Ext(hdr.Name), inputExt) {
+			if err := tw.WriteHeader(hdr); err != nil {
+				return converted, skipped, fmt.Errorf("failed to write %s: %w", hdr.Name, err)
+			}
+			if _, err := io.Copy(tw, tr); err != nil {
+				return converted, skipped, fmt.Errorf("failed to write %s: %w", hdr.Name, err)
+			}
+			continue
+		}
+
+		if hdr.ModTime.Before(since) {
+			skipped++
+			continue
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return converted, skipped, fmt.Errorf("failed to read %s: %w", hdr.Name, err)
+		}
+
+		// Key --explain notes by entry as well as by JSON Pointer
+		entryOpts := opts
+		if opts.Explain != nil {
+			entryOpts.Explain = &linePrefixWriter{w: opts.Explain, prefix: hdr.Name + ":"}
+		}
+
+		outputData, err := convertJSONTo(string(content), entryOpts)
+		if err != nil {
+			return converted, skipped, fmt.Errorf("%s: %w", hdr.Name, err)
+		}
+
+		hdr.Name = strings.TrimSuffix(hdr.Name, path.Ext(hdr.Name)) + outputExtensions[opts.Format]
+		hdr.Size = int64(len(outputData))
+		if err := tw.WriteHeader(hdr); err != nil {
+			return converted, skipped, fmt.Errorf("failed to write %s: %w", hdr.Name, err)
+		}
+		if _, err := io.WriteString(tw, outputData); err != nil {
+			return converted, skipped, fmt.Errorf("failed to write %s: %w", hdr.Name, err)
+		}
+		converted++
+	}
+
+	if err := tw.Close(); err != nil {
+		return converted, skipped, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if gw != nil {
+		if err := gw.Close(); err != nil {
+			return converted, skipped, fmt.Errorf("failed to write gzip stream: %w", err)
+		}
+	}
+
+	return converted, skipped, nil
+}
+
+// linePrefixWriter writes prefix at the start of every line written to w
+type linePrefixWriter struct {
+	w       io.Writer
+	prefix  string
+	midLine bool
+}
+
+func (p *linePrefixWriter) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) > 0 {
+		if !p.midLine {
+			if _, err := io.WriteString(p.w, p.prefix); err != nil {
+				return written, err
+			}
+			p.midLine = true
+		}
+
+		line := b
+		if i := bytes.IndexByte(b, '\n'); i >= 0 {
+			line = b[:i+1]
+			p.midLine = false
+		}
+		n, err := p.w.Write(line)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		b = b[len(line):]
+	}
+	return written, nil
+}
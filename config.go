@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Config is the optional json2yaml configuration file, e.g.
+//
+//	{"indent": {"yaml": 4, "html": 4}}
+type Config struct {
+	// Indent overrides the default indentation per output format
+	Indent map[string]int `json:"indent"`
+}
+
+// defaultConfigPath returns the configuration file used when --config is not
+// given, or "" if the user configuration directory is unknown
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "json2yaml", "config.json")
+}
+
+// loadConfig reads the configuration file at path. A missing file is only
+// an error when the path was given explicitly.
+func loadConfig(path string, explicit bool) (Config, error) {
+	var cfg Config
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("error reading config file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	for format, indent := range cfg.Indent {
+		if !slices.Contains(formatFlags["indent"], format) {
+			return cfg, fmt.Errorf("config file %s: indent cannot be set for format %q", path, format)
+		}
+		if err := validateIndent(int64(indent)); err != nil {
+			return cfg, fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+
+	return cfg, nil
+}
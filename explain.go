@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxExactFloatInt is the largest integer float64 can hold exactly (2^53)
+var maxExactFloatInt = new(big.Int).Lsh(big.NewInt(1), 53)
+
+// yaml11Bools are plain scalars that YAML 1.1 parsers read as booleans.
+// yaml.v3 quotes them for compatibility even though YAML 1.2 does not.
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"n": true, "N": true, "no": true, "No": true, "NO": true,
+	"on": true, "On": true, "ON": true,
+	"off": true, "Off": true, "OFF": true,
+}
+
+// writeExplanation writes one note per non-obvious conversion decision to
+// w, keyed by the JSON Pointer of the value it concerns
+func writeExplanation(w io.Writer, data interface{}, opts ConvertOptions) {
+	var notes []string
+	explainValue(&notes, "", data, opts)
+
+	if items, ok := data.([]interface{}); ok && opts.Split && opts.OmitEmptyDocuments {
+		for i, item := range items {
+			if isEmptyDocument(item) {
+				notes = append(notes, fmt.Sprintf("/%d: omitted empty document %s (--omit-empty-documents)", i, compactJSON(item)))
+			}
+		}
+	}
+
+	for _, note := range notes {
+		fmt.Fprintln(w, note)
+	}
+}
+
+func explainValue(notes *[]string, pointer string, value interface{}, opts ConvertOptions) {
+	label := pointer
+	if label == "" {
+		label = "(root)"
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			child := pointer + "/" + escapePointerToken(key)
+			if reason := yamlStringNote(key); reason != "" {
+				*notes = append(*notes, fmt.Sprintf("%s: key %s", child, reason))
+			}
+			explainValue(notes, child, v[key], opts)
+		}
+	case []interface{}:
+		for i, item := range v {
+			explainValue(notes, pointer+"/"+strconv.Itoa(i), item, opts)
+		}
+	case json.Number:
+		if pointerSelected(pointer, opts.NumbersAsStrings) {
+			*notes = append(*notes, fmt.Sprintf("%s: rendered %s as the string %q (--numbers-as-strings)", label, v, v.String()))
+			return
+		}
+		if reason := numberNote(v); reason != "" {
+			*notes = append(*notes, fmt.Sprintf("%s: %s", label, reason))
+		}
+	case string:
+		if reason := yamlStringNote(v); reason != "" {
+			*notes = append(*notes, fmt.Sprintf("%s: %s", label, reason))
+		}
+	}
+}
+
+// numberNote explains numbers whose output differs from what decoding
+// through float64 would have produced
+func numberNote(n json.Number) string {
+	literal := n.String()
+
+	if !strings.ContainsAny(literal, ".eE") {
+		i, ok := new(big.Int).SetString(literal, 10)
+		if !ok || new(big.Int).Abs(i).Cmp(maxExactFloatInt) <= 0 {
+			return ""
+		}
+		f, _ := new(big.Float).SetInt(i).Float64()
+		exact := strconv.FormatFloat(f, 'f', -1, 64)
+		if numberTag(literal) == "" {
+			return fmt.Sprintf("kept literal %s via UseNumber without a tag; it does not fit a 64-bit integer, so YAML readers may load it as a float (float64 would give %s)", literal, exact)
+		}
+		return fmt.Sprintf("rendered %s as integer via UseNumber (float64 would give %s)", literal, exact)
+	}
+
+	f, err := strconv.ParseFloat(literal, 64)
+	if err != nil {
+		return fmt.Sprintf("kept literal %s via UseNumber without a tag; it is out of float64 range", literal)
+	}
+	if formatted := strconv.FormatFloat(f, 'g', -1, 64); formatted != literal {
+		return fmt.Sprintf("kept float literal %s via UseNumber (float64 would print %s)", literal, formatted)
+	}
+	return ""
+}
+
+// yamlStringNote explains why a string is not written as a plain scalar
+func yamlStringNote(s string) string {
+	out, err := yaml.Marshal(s)
+	if err != nil {
+		return ""
+	}
+
+	switch out[0] {
+	case '|', '>':
+		return fmt.Sprintf("rendered %q as a block scalar because it contains newlines", s)
+	case '"', '\'':
+		return fmt.Sprintf("quoted %q because %s", s, quotingReason(s))
+	}
+	return ""
+}
+
+// quotingReason names what a string would be read as if written unquoted
+func quotingReason(s string) string {
+	if s == "" {
+		return "an empty plain scalar would parse as null"
+	}
+	if yaml11Bools[s] {
+		return "YAML 1.1 parsers read it as a boolean"
+	}
+
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(s), &v); err == nil {
+		switch v.(type) {
+		case nil:
+			return "it would parse as null"
+		case bool:
+			return "it would parse as a boolean"
+		case int, int64, uint64, float64:
+			return "it would parse as a number"
+		case time.Time:
+			return "it would parse as a timestamp"
+		}
+	}
+	return "it contains characters with special meaning in YAML"
+}
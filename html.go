@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+var htmlPreviewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>json2yaml - YAML Preview</title>
+    <style>
+{{.Style}}
+.yaml-key { color: #5dade2; }
+.yaml-string { color: #a9dfbf; }
+.yaml-number { color: #f5b041; }
+.yaml-literal { color: #c39bd3; }
+.yaml-punct { color: #95a5a6; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <header>
+            <h1>json2yaml</h1>
+            <p>YAML Preview</p>
+        </header>
+
+        <main>
+            <div class="result-section">
+                <h2>Conversion Result</h2>
+                <div class="result-content">
+                    <pre>{{.Body}}</pre>
+                </div>
+            </div>
+        </main>
+    </div>
+</body>
+</html>
+`))
+
+// renderHTMLPreview wraps YAML in a standalone, syntax-highlighted HTML page
+// styled with the embedded web GUI stylesheet
+func renderHTMLPreview(yamlContent string) (string, error) {
+	style, err := webFS.ReadFile("web/style.css")
+	if err != nil {
+		return "", fmt.Errorf("failed to load stylesheet: %w", err)
+	}
+
+	var buf bytes.Buffer
+	err = htmlPreviewTemplate.Execute(&buf, struct {
+		Style template.CSS
+		Body  template.HTML
+	}{
+		Style: template.CSS(style),
+		Body:  highlightYAML(yamlContent),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render HTML: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+var yamlNumberPattern = regexp.MustCompile(`^[-+]?(\.inf|\.Inf|\.INF|\.nan|\.NaN|\.NAN|[0-9][0-9_]*(\.[0-9]*)?([eE][-+]?[0-9]+)?|0x[0-9a-fA-F]+|0o[0-7]+)$`)
+
+// highlightYAML marks up keys and scalars of YAML produced by marshalYAML.
+// It is a line based highlighter, not a parser: it only needs to understand
+// the block style that yaml.v3 emits.
+func highlightYAML(yamlContent string) template.HTML {
+	var sb strings.Builder
+	blockIndent := -1 // indentation of the line that opened a block scalar
+
+	lines := strings.SplitAfter(yamlContent, "\n")
+	for _, line := range lines {
+		text := strings.TrimSuffix(line, "\n")
+		newline := line[len(text):]
+		indent := len(text) - len(strings.TrimLeft(text, " "))
+
+		// Lines belonging to a literal or folded block scalar
+		if blockIndent >= 0 {
+			if strings.TrimSpace(text) == "" || indent > blockIndent {
+				writeSpan(&sb, "yaml-string", text)
+				sb.WriteString(newline)
+				continue
+			}
+			blockIndent = -1
+		}
+
+		sb.WriteString(text[:indent])
+		rest := text[indent:]
+		column := indent
+
+		// Sequence entries, possibly nested on one line ("- - a")
+		for strings.HasPrefix(rest, "- ") || rest == "-" {
+			writeSpan(&sb, "yaml-punct", "-")
+			if rest == "-" {
+				rest = ""
+				break
+			}
+			sb.WriteString(" ")
+			rest = rest[2:]
+			column += 2
+		}
+
+		switch {
+		case rest == "":
+		case strings.HasPrefix(rest, "#"):
+			writeSpan(&sb, "yaml-punct", rest)
+		case rest == "---" || rest == "...":
+			writeSpan(&sb, "yaml-punct", rest)
+		default:
+			key, value, isMapping := splitYAMLKey(rest)
+			if isMapping {
+				writeSpan(&sb, "yaml-key", key)
+				writeSpan(&sb, "yaml-punct", ":")
+				if value == "" {
+					break
+				}
+				sb.WriteString(" ")
+				rest = value
+			}
+			if isBlockScalarHeader(rest) {
+				blockIndent = column
+			}
+			writeYAMLScalar(&sb, rest)
+		}
+		sb.WriteString(newline)
+	}
+
+	return template.HTML(sb.String())
+}
+
+// splitYAMLKey splits "key: value" and "key:" lines. Quoted keys are
+// supported because yaml.v3 quotes keys that would otherwise be ambiguous.
+func splitYAMLKey(s string) (key, value string, ok bool) {
+	end := 0
+	if s[0] == '"' || s[0] == '\'' {
+		end = closingQuote(s)
+		if end < 0 {
+			return "", "", false
+		}
+		end++
+	}
+
+	if i := strings.Index(s[end:], ": "); i >= 0 {
+		return s[:end+i], s[end+i+2:], true
+	}
+	if strings.HasSuffix(s, ":") && s[0] != '[' && s[0] != '{' {
+		return s[:len(s)-1], "", true
+	}
+	return "", "", false
+}
+
+// closingQuote returns the index of the quote that closes the string
+// starting at s[0], or -1 if there is none
+func closingQuote(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case quote == '\'' && s[i] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func isBlockScalarHeader(s string) bool {
+	return len(s) > 0 && (s[0] == '|' || s[0] == '>') && strings.Trim(s[1:], "+-0123456789") == ""
+}
+
+func writeYAMLScalar(sb *strings.Builder, s string) {
+	switch {
+	case isBlockScalarHeader(s), s == "{}", s == "[]":
+		writeSpan(sb, "yaml-punct", s)
+	case s[0] == '"' || s[0] == '\'':
+		writeSpan(sb, "yaml-string", s)
+	case s == "true" || s == "false" || s == "null" || s == "~":
+		writeSpan(sb, "yaml-literal", s)
+	case yamlNumberPattern.MatchString(s):
+		writeSpan(sb, "yaml-number", s)
+	default:
+		writeSpan(sb, "yaml-string", s)
+	}
+}
+
+func writeSpan(sb *strings.Builder, class string, text string) {
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(sb, `<span class="%s">%s</span>`, class, template.HTMLEscapeString(text))
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// recordSeparator frames each record of a JSON text sequence (RFC 7464)
+const recordSeparator = 0x1e
+
+// decodeJSONSeq parses a JSON text sequence into a slice of records.
+// Whitespace around each record, including the trailing newline writers
+// usually emit, is ignored and empty frames are skipped.
+func decodeJSONSeq(content []byte) ([]interface{}, error) {
+	records := []interface{}{}
+
+	frames := bytes.Split(content, []byte{recordSeparator})
+	for _, frame := range frames {
+		frame = bytes.TrimSpace(frame)
+		if len(frame) == 0 {
+			continue
+		}
+
+		var record interface{}
+		if err := decodeJSON(frame, &record); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON record %d: %w", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
@@ -1,11 +1,20 @@
 package main
 
 import (
+	"bytes"
+	"cmp"
 	"context"
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/urfave/cli/v3"
 	"gopkg.in/yaml.v3"
@@ -13,23 +22,277 @@ import (
 
 // convertJSONToYAML converts JSON content to YAML format
 func convertJSONToYAML(jsonContent string) (string, error) {
-	var data interface{}
-	if err := json.Unmarshal([]byte(jsonContent), &data); err != nil {
-		return "", fmt.Errorf("failed to parse JSON: %w", err)
-	}
+	return convertJSONTo(jsonContent, ConvertOptions{Format: formatYAML})
+}
+
+// Input formats supported by the --from flag
+const (
+	formatJSON    = "json"
+	formatJSONSeq = "json-seq" // RFC 7464 JSON text sequence
+)
+
+var inputFormats = []string{formatJSON, formatJSONSeq}
 
-	yamlBytes, err := yaml.Marshal(data)
+// Output formats supported by the --to flag
+const (
+	formatYAML     = "yaml"
+	formatPointers = "pointers"
+	formatHTML     = "html"
+)
+
+var outputFormats = []string{formatYAML, formatPointers, formatHTML}
+
+// defaultIndents is the indentation used for each format when neither
+// --indent nor the config file set one
+var defaultIndents = map[string]int{
+	formatYAML: 2,
+	formatHTML: 2,
+}
+
+// ConvertOptions holds the settings that affect conversion output
+type ConvertOptions struct {
+	From               string // input format, "" means json
+	Format             string
+	Indent             int    // spaces per nesting level, 0 means the format default
+	Split              bool   // emit each element of a top-level array as its own YAML document
+	OmitEmptyDocuments bool   // with Split, skip null, {} and [] elements
+	Patch              string // patch document applied to the input before marshalling
+	PatchType          string
+	NumbersAsStrings   []string  // JSON Pointers whose numbers are emitted as strings, "" for all
+	Explain            io.Writer // receives notes on non-obvious conversion decisions, if set
+}
+
+// convertJSONTo converts JSON content to the format given in opts
+func convertJSONTo(jsonContent string, opts ConvertOptions) (string, error) {
+	data, err := decodeInput(jsonContent, opts.From)
 	if err != nil {
+		return "", err
+	}
+
+	if opts.Patch != "" {
+		data, err = applyPatch(data, opts.PatchType, opts.Patch)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if opts.Explain != nil {
+		writeExplanation(opts.Explain, data, opts)
+	}
+
+	if len(opts.NumbersAsStrings) > 0 {
+		data = numbersToStrings(data, opts.NumbersAsStrings)
+	}
+
+	indent := opts.Indent
+	if indent == 0 {
+		indent = defaultIndents[opts.Format]
+	}
+
+	switch opts.Format {
+	case formatYAML:
+		return marshalYAML(yamlDocuments(data, opts), indent)
+	case formatHTML:
+		yamlContent, err := marshalYAML(yamlDocuments(data, opts), indent)
+		if err != nil {
+			return "", err
+		}
+		return renderHTMLPreview(yamlContent)
+	case formatPointers:
+		return marshalPointers(data)
+	default:
+		return "", fmt.Errorf("unsupported output format: %s", opts.Format)
+	}
+}
+
+// decodeInput parses content in the given input format. A JSON text
+// sequence is decoded into a slice holding one element per record.
+func decodeInput(content string, from string) (interface{}, error) {
+	switch from {
+	case "", formatJSON:
+		var data interface{}
+		if err := decodeJSON([]byte(content), &data); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		return data, nil
+	case formatJSONSeq:
+		return decodeJSONSeq([]byte(content))
+	default:
+		return nil, fmt.Errorf("unsupported input format: %s", from)
+	}
+}
+
+// yamlDocuments returns the documents to write for data. With Split, each
+// element of a top-level array becomes its own document, and with
+// OmitEmptyDocuments null, {} and [] elements are dropped.
+func yamlDocuments(data interface{}, opts ConvertOptions) []interface{} {
+	items, ok := data.([]interface{})
+	if !ok || !opts.Split {
+		return []interface{}{data}
+	}
+
+	if !opts.OmitEmptyDocuments {
+		return items
+	}
+
+	docs := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if !isEmptyDocument(item) {
+			docs = append(docs, item)
+		}
+	}
+	return docs
+}
+
+func isEmptyDocument(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// marshalYAML encodes docs as a YAML stream with the given indentation.
+// No documents, e.g. from splitting an empty array, give empty output.
+func marshalYAML(docs []interface{}, indent int) (string, error) {
+	// yaml.Encoder fails on Close if nothing was encoded
+	if len(docs) == 0 {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	for _, doc := range docs {
+		if err := enc.Encode(yamlValue(doc)); err != nil {
+			return "", fmt.Errorf("failed to marshal YAML: %w", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
 		return "", fmt.Errorf("failed to marshal YAML: %w", err)
 	}
 
-	return string(yamlBytes), nil
+	return buf.String(), nil
+}
+
+// validateInputFormat checks the value given to --from
+func validateInputFormat(format string) error {
+	for _, f := range inputFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported input format %q (supported: %s)", format, strings.Join(inputFormats, ", "))
+}
+
+// validateOutputFormat checks the value given to --to
+func validateOutputFormat(format string) error {
+	for _, f := range outputFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output format %q (supported: %s)", format, strings.Join(outputFormats, ", "))
+}
+
+// validatePointers checks that every value is a JSON Pointer
+func validatePointers(pointers []string) error {
+	for _, pointer := range pointers {
+		if _, err := parsePointer(pointer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateIndent checks the value given to --indent
+func validateIndent(indent int64) error {
+	if indent < 1 || indent > 16 {
+		return fmt.Errorf("indent must be between 1 and 16, got %d", indent)
+	}
+	return nil
+}
+
+// formatFlags lists the style flags that only have an effect for some
+// output formats. Flags not listed here apply to every format.
+var formatFlags = map[string][]string{
+	"indent":               {formatYAML, formatHTML},
+	"split":                {formatYAML, formatHTML},
+	"omit-empty-documents": {formatYAML, formatHTML},
+	"explain":              {formatYAML, formatHTML},
+}
+
+// validateFormatFlags rejects style flags that would be silently ignored
+// by the output format chosen with --to
+func validateFormatFlags(ctx context.Context, cmd *cli.Command) (context.Context, error) {
+	format := cmd.String("to")
+
+	flags := make([]string, 0, len(formatFlags))
+	for flag := range formatFlags {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+
+	var invalid []string
+	for _, flag := range flags {
+		if cmd.IsSet(flag) && !slices.Contains(formatFlags[flag], format) {
+			invalid = append(invalid, "--"+flag)
+		}
+	}
+	if len(invalid) == 0 {
+		return ctx, nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s cannot be used with --to %s\n", strings.Join(invalid, ", "), format)
+	sb.WriteString("Style flags apply to these formats:")
+	for _, flag := range flags {
+		fmt.Fprintf(&sb, "\n  --%s: %s", flag, strings.Join(formatFlags[flag], ", "))
+	}
+	return ctx, fmt.Errorf("%s", sb.String())
+}
+
+// resolveIndent picks the indentation for format with the precedence
+// --indent > config file > format default
+func resolveIndent(cmd *cli.Command, cfg Config, format string) int {
+	if cmd.IsSet("indent") {
+		return int(cmd.Int("indent"))
+	}
+	if indent, ok := cfg.Indent[format]; ok {
+		return indent
+	}
+	return defaultIndents[format]
 }
 
 func convert(ctx context.Context, cmd *cli.Command) error {
 	inputFile := cmd.String("input")
 	outputFile := cmd.String("output")
 
+	configFile := cmd.String("config")
+	cfg, err := loadConfig(cmp.Or(configFile, defaultConfigPath()), configFile != "")
+	if err != nil {
+		return err
+	}
+
+	format := cmd.String("to")
+	opts := ConvertOptions{
+		From:               cmd.String("from"),
+		Format:             format,
+		Indent:             resolveIndent(cmd, cfg, format),
+		Split:              cmd.Bool("split"),
+		OmitEmptyDocuments: cmd.Bool("omit-empty-documents"),
+		PatchType:          cmd.String("patch-type"),
+		NumbersAsStrings:   cmd.StringSlice("numbers-as-strings"),
+	}
+
+	if cmd.Bool("explain") {
+		opts.Explain = os.Stderr
+	}
+
 	// Handle positional arguments if flags not provided
 	if inputFile == "" && cmd.Args().Len() > 0 {
 		inputFile = cmd.Args().Get(0)
@@ -39,36 +302,108 @@ func convert(ctx context.Context, cmd *cli.Command) error {
 		outputFile = cmd.Args().Get(1)
 	}
 
+	if cmd.Bool("omit-empty-documents") && !cmd.Bool("split") {
+		return fmt.Errorf("--omit-empty-documents requires --split")
+	}
+
+	if cmd.IsSet("patch-type") && cmd.String("patch") == "" {
+		return fmt.Errorf("--patch-type requires --patch")
+	}
+
+	if cmd.IsSet("since") && !cmd.Bool("stream-archive") {
+		return fmt.Errorf("--since requires --stream-archive")
+	}
+
+	if patchFile := cmd.String("patch"); patchFile != "" {
+		patchBytes, err := os.ReadFile(patchFile)
+		if err != nil {
+			return fmt.Errorf("error reading patch file: %w", err)
+		}
+		opts.Patch = string(patchBytes)
+	}
+
+	// Convert a tar stream from stdin to stdout
+	if cmd.Bool("stream-archive") {
+		if inputFile != "" || outputFile != "" || cmd.Bool("checksum") {
+			return fmt.Errorf("--stream-archive reads from stdin and writes to stdout; input, output and --checksum cannot be used")
+		}
+
+		var since time.Time
+		if value := cmd.String("since"); value != "" {
+			since, err = parseSince(value, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+
+		converted, skipped, err := convertArchive(os.Stdin, os.Stdout, opts, since)
+		if err != nil {
+			return err
+		}
+		if cmd.IsSet("since") {
+			fmt.Fprintf(os.Stderr, "Successfully converted %d JSON files (%d skipped by --since)\n", converted, skipped)
+		} else {
+			fmt.Fprintf(os.Stderr, "Successfully converted %d JSON files\n", converted)
+		}
+		return nil
+	}
+
 	if inputFile == "" {
 		return fmt.Errorf("input file is required")
 	}
 
+	if cmd.Bool("checksum") && outputFile == "" {
+		return fmt.Errorf("--checksum requires an output file")
+	}
+
 	// Read input JSON file
 	fileBytes, err := os.ReadFile(inputFile)
 	if err != nil {
 		return fmt.Errorf("error reading file: %w", err)
 	}
 
-	// Convert JSON to YAML
-	yamlData, err := convertJSONToYAML(string(fileBytes))
+	// Convert JSON to the requested format
+	outputData, err := convertJSONTo(string(fileBytes), opts)
 	if err != nil {
 		return err
 	}
 
 	// Write output
 	if outputFile != "" {
-		err = os.WriteFile(outputFile, []byte(yamlData), 0o644)
+		err = os.WriteFile(outputFile, []byte(outputData), 0o644)
 		if err != nil {
 			return fmt.Errorf("error writing output file: %w", err)
 		}
 		fmt.Fprintf(os.Stderr, "Successfully converted %s to %s\n", inputFile, outputFile)
+
+		if cmd.Bool("checksum") {
+			checksumFile, err := writeChecksumFile(outputFile, []byte(outputData))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Wrote checksum to %s\n", checksumFile)
+		}
 	} else {
-		fmt.Print(yamlData)
+		fmt.Print(outputData)
 	}
 
 	return nil
 }
 
+// writeChecksumFile writes the SHA-256 of data to <outputFile>.sha256 in the
+// format produced by sha256sum, so it can be verified with "sha256sum -c"
+func writeChecksumFile(outputFile string, data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	line := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), filepath.Base(outputFile))
+
+	checksumFile := outputFile + ".sha256"
+	if err := os.WriteFile(checksumFile, []byte(line), 0o644); err != nil {
+		return "", fmt.Errorf("error writing checksum file: %w", err)
+	}
+
+	return checksumFile, nil
+}
+
 func webMode(ctx context.Context, cmd *cli.Command) error {
 	port := cmd.String("port")
 	if port == "" {
@@ -78,7 +413,37 @@ func webMode(ctx context.Context, cmd *cli.Command) error {
 	fmt.Println("json2yaml - Web Mode")
 	fmt.Println("Starting web interface...")
 
-	return startWebServer(port)
+	return startWebServer(ServerOptions{
+		Port:           port,
+		DevAssets:      cmd.String("dev-assets"),
+		EnableFormats:  cmd.StringSlice("enable-formats"),
+		DisableFormats: cmd.StringSlice("disable-formats"),
+		OpenBrowser:    true,
+		ServeGUI:       true,
+		AutoShutdown:   true,
+		Heartbeat:      true,
+	})
+}
+
+func serveMode(ctx context.Context, cmd *cli.Command) error {
+	port := cmd.String("port")
+	if port == "" {
+		port = "8080"
+	}
+
+	fmt.Println("json2yaml - Server Mode")
+	fmt.Println("Starting API server...")
+
+	return startWebServer(ServerOptions{
+		Port:           port,
+		EnableFormats:  cmd.StringSlice("enable-formats"),
+		DisableFormats: cmd.StringSlice("disable-formats"),
+		AutoShutdown:   !cmd.Bool("no-auto-shutdown"),
+		AccessLog:      cmd.Bool("access-log"),
+		ReadTimeout:    cmd.Duration("read-timeout"),
+		WriteTimeout:   cmd.Duration("write-timeout"),
+		IdleTimeout:    cmd.Duration("idle-timeout"),
+	})
 }
 
 func main() {
@@ -86,7 +451,13 @@ func main() {
 	if len(os.Args) == 1 {
 		fmt.Println("json2yaml - Web Mode")
 		fmt.Println("Starting web interface...")
-		err := startWebServer("8080")
+		err := startWebServer(ServerOptions{
+			Port:         "8080",
+			OpenBrowser:  true,
+			ServeGUI:     true,
+			AutoShutdown: true,
+			Heartbeat:    true,
+		})
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -104,8 +475,28 @@ This is a sample tool demonstrating how to add a Web GUI to a CLI tool.
 Usage:
   json2yaml                      # Start web interface
   json2yaml web                  # Start web interface
+  json2yaml serve                # Start API server daemon
   json2yaml input.json           # Convert and output to stdout
-  json2yaml input.json output.yaml  # Convert and save to file`,
+  json2yaml input.json output.yaml  # Convert and save to file
+  json2yaml --to pointers input.json  # List "jsonpointer = value" lines
+  json2yaml --to html input.json preview.html  # Syntax-highlighted HTML page
+  json2yaml --patch patch.json input.json  # Apply a JSON Merge Patch first
+  json2yaml --from json-seq --split events.json-seq  # One YAML document per record
+  json2yaml --explain input.json  # Explain quoting and number handling on stderr
+  tar czf - configs/ | json2yaml --stream-archive > configs.tar.gz
+  tar cf - configs/ | json2yaml --stream-archive --since 24h > changed.tar
+
+Numbers:
+  Input is decoded with UseNumber, so every number keeps its JSON literal
+  (e.g. 9007199254740993 or 1.50) instead of being rounded through float64.
+  --numbers-as-strings emits that literal as a quoted string, e.g.
+  --numbers-as-strings /phone --numbers-as-strings /zip, or
+  --numbers-as-strings "" for every number in the document.
+
+Indentation:
+  The indentation of yaml and html output is chosen with the precedence
+  --indent > "indent" in the config file > format default (2 spaces).
+  Example config file: {"indent": {"yaml": 4}}`,
 		ArgsUsage: "[input.json] [output.yaml]",
 		Commands: []*cli.Command{
 			{
@@ -119,6 +510,76 @@ Usage:
 						Usage:   "Port to run web server on",
 						Value:   "8080",
 					},
+					&cli.StringSliceFlag{
+						Name:  "enable-formats",
+						Usage: "Only accept these from/to formats in conversion requests; input and output formats are restricted separately (default: all)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "disable-formats",
+						Usage: "Reject these from/to formats in conversion requests",
+					},
+					&cli.StringFlag{
+						Name:  "dev-assets",
+						Usage: "Serve web assets from this directory, falling back to the embedded copy for missing files",
+					},
+				},
+			},
+			{
+				Name:  "serve",
+				Usage: "Start a long-lived API server (no browser, no auto-shutdown)",
+				Description: `serve runs the conversion API as a daemon for shared use.
+
+Unlike web, it does not open a browser, does not serve the GUI and
+does not exit when clients disconnect. With --no-auto-shutdown=false it
+exits once no client has been connected for 5 seconds; /heartbeat is not
+used, so plain API clients keep it alive.
+
+Probe endpoints (e.g. for Kubernetes):
+  /livez   liveness and startup probes: 200 once the process is up
+  /readyz  readiness probe: 200 once the startup self-test conversion
+           passed and the listener accepts connections, 503 otherwise
+           and while shutting down`,
+				Action: serveMode,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "port",
+						Aliases: []string{"p"},
+						Usage:   "Port to run API server on",
+						Value:   "8080",
+					},
+					&cli.StringSliceFlag{
+						Name:  "enable-formats",
+						Usage: "Only accept these from/to formats in conversion requests; input and output formats are restricted separately (default: all)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "disable-formats",
+						Usage: "Reject these from/to formats in conversion requests",
+					},
+					&cli.BoolFlag{
+						Name:  "no-auto-shutdown",
+						Usage: "Keep running regardless of client connections (set to false to exit 5 seconds after the last connection closes)",
+						Value: true,
+					},
+					&cli.BoolFlag{
+						Name:  "access-log",
+						Usage: "Log every request to stderr",
+						Value: true,
+					},
+					&cli.DurationFlag{
+						Name:  "read-timeout",
+						Usage: "Maximum duration for reading an entire request",
+						Value: 30 * time.Second,
+					},
+					&cli.DurationFlag{
+						Name:  "write-timeout",
+						Usage: "Maximum duration before timing out writes of a response",
+						Value: 30 * time.Second,
+					},
+					&cli.DurationFlag{
+						Name:  "idle-timeout",
+						Usage: "Maximum time to wait for the next request on keep-alive connections",
+						Value: 120 * time.Second,
+					},
 				},
 			},
 		},
@@ -133,7 +594,70 @@ Usage:
 				Aliases: []string{"o"},
 				Usage:   "Output YAML file path (optional, defaults to stdout)",
 			},
+			&cli.StringFlag{
+				Name:      "from",
+				Usage:     "Input format: json or json-seq (RFC 7464 records framed with 0x1e, converted to a sequence)",
+				Value:     formatJSON,
+				Validator: validateInputFormat,
+			},
+			&cli.StringFlag{
+				Name:      "to",
+				Usage:     "Output format: yaml, pointers (one \"jsonpointer = value\" line per leaf) or html (highlighted YAML preview page)",
+				Value:     formatYAML,
+				Validator: validateOutputFormat,
+			},
+			&cli.IntFlag{
+				Name:        "indent",
+				Usage:       "Number of spaces per indentation level (yaml and html only, overrides the config file)",
+				DefaultText: "config file, else 2 for yaml/html",
+				Validator:   validateIndent,
+			},
+			&cli.BoolFlag{
+				Name:  "split",
+				Usage: "Write each element of a top-level array (or each json-seq record) as a separate YAML document",
+			},
+			&cli.BoolFlag{
+				Name:  "omit-empty-documents",
+				Usage: "With --split, skip documents for null, {} and [] elements",
+			},
+			&cli.StringSliceFlag{
+				Name:      "numbers-as-strings",
+				Usage:     "Emit numbers at or below these JSON Pointers as quoted strings (\"\" for the whole document)",
+				Validator: validatePointers,
+			},
+			&cli.BoolFlag{
+				Name:  "explain",
+				Usage: "Print notes on non-obvious conversion decisions (quoting, number handling) to stderr, keyed by JSON Pointer",
+			},
+			&cli.StringFlag{
+				Name:  "config",
+				Usage: "Configuration file (default: json2yaml/config.json in the user config directory)",
+			},
+			&cli.StringFlag{
+				Name:  "patch",
+				Usage: "Patch file applied to the input before conversion",
+			},
+			&cli.StringFlag{
+				Name:      "patch-type",
+				Usage:     "Patch format: merge (RFC 7386 JSON Merge Patch) or json (RFC 6902 JSON Patch)",
+				Value:     patchTypeMerge,
+				Validator: validatePatchType,
+			},
+			&cli.BoolFlag{
+				Name:  "stream-archive",
+				Usage: "Read a tar archive (optionally gzip-compressed) from stdin and write a tar of converted files to stdout",
+			},
+			&cli.StringFlag{
+				Name:      "since",
+				Usage:     "With --stream-archive, skip input entries modified before this point: a duration such as 24h, or a timestamp such as 2006-01-02 or 2006-01-02T15:04:05Z",
+				Validator: validateSince,
+			},
+			&cli.BoolFlag{
+				Name:  "checksum",
+				Usage: "Write the SHA-256 of the output file to <output>.sha256 (sha256sum format)",
+			},
 		},
+		Before: validateFormatFlags,
 		Action: convert,
 	}
 
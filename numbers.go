@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// decodeJSON unmarshals JSON with UseNumber, so numbers are kept as
+// json.Number holding their original literal instead of being rounded to
+// float64. Large integer IDs therefore survive the conversion unchanged.
+func decodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		if err == nil {
+			err = fmt.Errorf("unexpected data after top-level value")
+		}
+		return err
+	}
+	return nil
+}
+
+// numberString is the literal of a number selected by --numbers-as-strings.
+// It encodes as a JSON string and is always double-quoted in YAML.
+type numberString string
+
+// numbersToStrings replaces numbers with their literal text at or below
+// any of the given JSON Pointers. The empty pointer selects the whole
+// document.
+func numbersToStrings(data interface{}, pointers []string) interface{} {
+	return numbersToStringsAt(data, "", pointers, false)
+}
+
+// pointerSelected reports whether pointer is at or below one of pointers
+func pointerSelected(pointer string, pointers []string) bool {
+	for _, p := range pointers {
+		if pointer == p || strings.HasPrefix(pointer, p+"/") || p == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func numbersToStringsAt(value interface{}, pointer string, pointers []string, selected bool) interface{} {
+	selected = selected || pointerSelected(pointer, pointers)
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = numbersToStringsAt(child, pointer+"/"+escapePointerToken(key), pointers, selected)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = numbersToStringsAt(child, pointer+"/"+strconv.Itoa(i), pointers, selected)
+		}
+		return v
+	case json.Number:
+		if selected {
+			return numberString(v)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// yamlValue prepares decoded JSON for the YAML encoder. json.Number is a
+// string type that yaml.v3 would quote, so numbers are turned into scalar
+// nodes that keep the JSON literal as an int or float, and numbers selected
+// by --numbers-as-strings into double-quoted strings.
+func yamlValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			m[key] = yamlValue(child)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, child := range v {
+			s[i] = yamlValue(child)
+		}
+		return s
+	case json.Number:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: numberTag(v.String()), Value: v.String()}
+	case numberString:
+		// Quote explicitly: yaml.v3 writes a plain scalar for literals it
+		// does not resolve as a number, such as 1e400, which YAML 1.2 core
+		// schema readers still load as a float
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Style: yaml.DoubleQuotedStyle, Value: string(v)}
+	default:
+		return v
+	}
+}
+
+// numberTag returns the YAML tag for a JSON number literal. Literals that
+// do not fit an int64/uint64 or float64 get no explicit tag, because
+// yaml.v3 cannot decode e.g. "!!int 99999999999999999999"; the plain
+// scalar keeps the literal and is still readable.
+func numberTag(literal string) string {
+	if strings.ContainsAny(literal, ".eE") {
+		if _, err := strconv.ParseFloat(literal, 64); err == nil {
+			return "!!float"
+		}
+		return ""
+	}
+
+	if _, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return "!!int"
+	}
+	if _, err := strconv.ParseUint(literal, 10, 64); err == nil {
+		return "!!int"
+	}
+	return ""
+}
+
+// jsonEqual compares decoded JSON values, treating numbers as equal when
+// they have the same value regardless of how they were written
+func jsonEqual(a, b interface{}) bool {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, child := range av {
+			other, ok := bv[key]
+			if !ok || !jsonEqual(child, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, okA := new(big.Rat).SetString(av.String())
+		y, okB := new(big.Rat).SetString(bv.String())
+		return okA && okB && x.Cmp(y) == 0
+	default:
+		return a == b
+	}
+}
@@ -0,0 +1,352 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Patch types supported by the --patch-type flag
+const (
+	patchTypeMerge = "merge" // RFC 7386 JSON Merge Patch
+	patchTypeJSON  = "json"  // RFC 6902 JSON Patch
+)
+
+var patchTypes = []string{patchTypeMerge, patchTypeJSON}
+
+// validatePatchType checks the value given to --patch-type
+func validatePatchType(patchType string) error {
+	for _, t := range patchTypes {
+		if t == patchType {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported patch type %q (supported: %s)", patchType, strings.Join(patchTypes, ", "))
+}
+
+// applyPatch applies a JSON Merge Patch or JSON Patch document to data
+func applyPatch(data interface{}, patchType string, patchContent string) (interface{}, error) {
+	switch patchType {
+	case patchTypeMerge:
+		var patch interface{}
+		if err := decodeJSON([]byte(patchContent), &patch); err != nil {
+			return nil, fmt.Errorf("failed to parse merge patch: %w", err)
+		}
+		return applyMergePatch(data, patch), nil
+	case patchTypeJSON:
+		var ops []map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(patchContent), &ops); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON patch: %w", err)
+		}
+		return applyJSONPatch(data, ops)
+	default:
+		return nil, fmt.Errorf("unsupported patch type: %s", patchType)
+	}
+}
+
+// applyMergePatch implements the MergePatch algorithm of RFC 7386
+func applyMergePatch(target interface{}, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+		} else {
+			targetObj[key] = applyMergePatch(targetObj[key], value)
+		}
+	}
+
+	return targetObj
+}
+
+// applyJSONPatch applies the operations of an RFC 6902 JSON Patch in order.
+// Errors name the index, op and path of the failing operation.
+func applyJSONPatch(data interface{}, ops []map[string]json.RawMessage) (interface{}, error) {
+	for i, op := range ops {
+		var name, path string
+		if err := decodePatchMember(op, "op", &name); err != nil {
+			return nil, fmt.Errorf("patch operation %d: %w", i, err)
+		}
+		if err := decodePatchMember(op, "path", &path); err != nil {
+			return nil, fmt.Errorf("patch operation %d (%s): %w", i, name, err)
+		}
+
+		var err error
+		data, err = applyJSONPatchOp(data, name, path, op)
+		if err != nil {
+			return nil, fmt.Errorf("patch operation %d (%s %s) failed: %w", i, name, path, err)
+		}
+	}
+
+	return data, nil
+}
+
+func applyJSONPatchOp(data interface{}, name string, path string, op map[string]json.RawMessage) (interface{}, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case "add":
+		var value interface{}
+		if err := decodePatchMember(op, "value", &value); err != nil {
+			return nil, err
+		}
+		return pointerAdd(data, tokens, value)
+	case "remove":
+		return pointerRemove(data, tokens)
+	case "replace":
+		var value interface{}
+		if err := decodePatchMember(op, "value", &value); err != nil {
+			return nil, err
+		}
+		if _, err := pointerGet(data, tokens); err != nil {
+			return nil, err
+		}
+		if len(tokens) == 0 {
+			return value, nil
+		}
+		return pointerModify(data, tokens, func(parent interface{}, key string) (interface{}, error) {
+			switch p := parent.(type) {
+			case map[string]interface{}:
+				p[key] = value
+				return p, nil
+			case []interface{}:
+				index, _ := strconv.Atoi(key) // checked by pointerGet above
+				p[index] = value
+				return p, nil
+			}
+			return nil, fmt.Errorf("cannot replace in a scalar value")
+		})
+	case "move", "copy":
+		var from string
+		if err := decodePatchMember(op, "from", &from); err != nil {
+			return nil, err
+		}
+		fromTokens, err := parsePointer(from)
+		if err != nil {
+			return nil, err
+		}
+		value, err := pointerGet(data, fromTokens)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		if name == "copy" {
+			return pointerAdd(data, tokens, deepCopy(value))
+		}
+		if from == path {
+			return data, nil
+		}
+		if strings.HasPrefix(path, from+"/") {
+			return nil, fmt.Errorf("cannot move %s into one of its children", from)
+		}
+		data, err = pointerRemove(data, fromTokens)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(data, tokens, value)
+	case "test":
+		var expected interface{}
+		if err := decodePatchMember(op, "value", &expected); err != nil {
+			return nil, err
+		}
+		actual, err := pointerGet(data, tokens)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(actual, expected) {
+			return nil, fmt.Errorf("test failed: value is %s", compactJSON(actual))
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %q", name)
+	}
+}
+
+// decodePatchMember decodes a required member of a patch operation
+func decodePatchMember(op map[string]json.RawMessage, member string, v interface{}) error {
+	raw, ok := op[member]
+	if !ok {
+		return fmt.Errorf("missing %q member", member)
+	}
+	if err := decodeJSON(raw, v); err != nil {
+		return fmt.Errorf("invalid %q member: %w", member, err)
+	}
+	return nil
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		token = strings.ReplaceAll(token, "~1", "/")
+		tokens[i] = strings.ReplaceAll(token, "~0", "~")
+	}
+	return tokens, nil
+}
+
+// pointerGet returns the value the tokens point to
+func pointerGet(data interface{}, tokens []string) (interface{}, error) {
+	for _, token := range tokens {
+		switch v := data.(type) {
+		case map[string]interface{}:
+			child, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", token)
+			}
+			data = child
+		case []interface{}:
+			index, err := arrayIndex(token, len(v)-1)
+			if err != nil {
+				return nil, err
+			}
+			data = v[index]
+		default:
+			return nil, fmt.Errorf("cannot look up %q in a scalar value", token)
+		}
+	}
+	return data, nil
+}
+
+// pointerModify walks to the container holding the last token and replaces
+// it with the result of fn. Arrays may be reallocated by fn, so every
+// level is reassigned on the way back up.
+func pointerModify(data interface{}, tokens []string, fn func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return fn(data, tokens[0])
+	}
+
+	switch v := data.(type) {
+	case map[string]interface{}:
+		child, ok := v[tokens[0]]
+		if !ok {
+			return nil, fmt.Errorf("member %q not found", tokens[0])
+		}
+		child, err := pointerModify(child, tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		v[tokens[0]] = child
+		return v, nil
+	case []interface{}:
+		index, err := arrayIndex(tokens[0], len(v)-1)
+		if err != nil {
+			return nil, err
+		}
+		child, err := pointerModify(v[index], tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		v[index] = child
+		return v, nil
+	default:
+		return nil, fmt.Errorf("cannot look up %q in a scalar value", tokens[0])
+	}
+}
+
+func pointerAdd(data interface{}, tokens []string, value interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	return pointerModify(data, tokens, func(parent interface{}, key string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[key] = value
+			return p, nil
+		case []interface{}:
+			if key == "-" {
+				return append(p, value), nil
+			}
+			index, err := arrayIndex(key, len(p))
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, nil)
+			copy(p[index+1:], p[index:])
+			p[index] = value
+			return p, nil
+		}
+		return nil, fmt.Errorf("cannot add %q to a scalar value", key)
+	})
+}
+
+func pointerRemove(data interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+
+	return pointerModify(data, tokens, func(parent interface{}, key string) (interface{}, error) {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := p[key]; !ok {
+				return nil, fmt.Errorf("member %q not found", key)
+			}
+			delete(p, key)
+			return p, nil
+		case []interface{}:
+			index, err := arrayIndex(key, len(p)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(p[:index], p[index+1:]...), nil
+		}
+		return nil, fmt.Errorf("cannot remove %q from a scalar value", key)
+	})
+}
+
+// arrayIndex parses an array index token and checks it is within 0..max
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if index > max {
+		return 0, fmt.Errorf("array index %d out of range", index)
+	}
+	return index, nil
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			m[key] = deepCopy(child)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, child := range v {
+			s[i] = deepCopy(child)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+func compactJSON(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
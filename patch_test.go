@@ -0,0 +1,255 @@
+package main
+
+import "testing"
+
+// jsonPatchTests are the examples of RFC 6902 appendix A, followed by edge
+// cases of array indexes and move. An empty want means the patch must fail.
+var jsonPatchTests = []struct {
+	name  string
+	doc   string
+	patch string
+	want  string
+}{
+	{
+		name:  "A.1 adding an object member",
+		doc:   `{"foo": "bar"}`,
+		patch: `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+		want:  `{"baz": "qux", "foo": "bar"}`,
+	},
+	{
+		name:  "A.2 adding an array element",
+		doc:   `{"foo": ["bar", "baz"]}`,
+		patch: `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+		want:  `{"foo": ["bar", "qux", "baz"]}`,
+	},
+	{
+		name:  "A.3 removing an object member",
+		doc:   `{"baz": "qux", "foo": "bar"}`,
+		patch: `[{"op": "remove", "path": "/baz"}]`,
+		want:  `{"foo": "bar"}`,
+	},
+	{
+		name:  "A.4 removing an array element",
+		doc:   `{"foo": ["bar", "qux", "baz"]}`,
+		patch: `[{"op": "remove", "path": "/foo/1"}]`,
+		want:  `{"foo": ["bar", "baz"]}`,
+	},
+	{
+		name:  "A.5 replacing a value",
+		doc:   `{"baz": "qux", "foo": "bar"}`,
+		patch: `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+		want:  `{"baz": "boo", "foo": "bar"}`,
+	},
+	{
+		name:  "A.6 moving a value",
+		doc:   `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+		patch: `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+		want:  `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+	},
+	{
+		name:  "A.7 moving an array element",
+		doc:   `{"foo": ["all", "grass", "cows", "eat"]}`,
+		patch: `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+		want:  `{"foo": ["all", "cows", "eat", "grass"]}`,
+	},
+	{
+		name:  "A.8 testing a value: success",
+		doc:   `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		patch: `[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`,
+		want:  `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+	},
+	{
+		name:  "A.9 testing a value: error",
+		doc:   `{"baz": "qux"}`,
+		patch: `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+	},
+	{
+		name:  "A.10 adding a nested member object",
+		doc:   `{"foo": "bar"}`,
+		patch: `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+		want:  `{"foo": "bar", "child": {"grandchild": {}}}`,
+	},
+	{
+		name:  "A.11 ignoring unrecognized elements",
+		doc:   `{"foo": "bar"}`,
+		patch: `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+		want:  `{"foo": "bar", "baz": "qux"}`,
+	},
+	{
+		name:  "A.12 adding to a nonexistent target",
+		doc:   `{"foo": "bar"}`,
+		patch: `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+	},
+	{
+		name:  "A.14 ~ escape ordering",
+		doc:   `{"/": 9, "~1": 10}`,
+		patch: `[{"op": "test", "path": "/~01", "value": 10}]`,
+		want:  `{"/": 9, "~1": 10}`,
+	},
+	{
+		name:  "A.15 comparing strings and numbers",
+		doc:   `{"/": 9, "~1": 10}`,
+		patch: `[{"op": "test", "path": "/~01", "value": "10"}]`,
+	},
+	{
+		name:  "A.16 adding an array value",
+		doc:   `{"foo": ["bar"]}`,
+		patch: `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+		want:  `{"foo": ["bar", ["abc", "def"]]}`,
+	},
+	{
+		name:  "add at the end index",
+		doc:   `{"foo": ["bar"]}`,
+		patch: `[{"op": "add", "path": "/foo/1", "value": "baz"}]`,
+		want:  `{"foo": ["bar", "baz"]}`,
+	},
+	{
+		name:  "add past the end index",
+		doc:   `{"foo": ["bar"]}`,
+		patch: `[{"op": "add", "path": "/foo/2", "value": "baz"}]`,
+	},
+	{
+		name:  "add with a leading zero index",
+		doc:   `{"foo": ["bar", "baz"]}`,
+		patch: `[{"op": "add", "path": "/foo/01", "value": "qux"}]`,
+	},
+	{
+		name:  "remove with - index",
+		doc:   `{"foo": ["bar"]}`,
+		patch: `[{"op": "remove", "path": "/foo/-"}]`,
+	},
+	{
+		name:  "remove out of range",
+		doc:   `{"foo": ["bar"]}`,
+		patch: `[{"op": "remove", "path": "/foo/1"}]`,
+	},
+	{
+		name:  "replace a missing member",
+		doc:   `{"foo": "bar"}`,
+		patch: `[{"op": "replace", "path": "/baz", "value": "qux"}]`,
+	},
+	{
+		name:  "replace the whole document",
+		doc:   `{"foo": "bar"}`,
+		patch: `[{"op": "replace", "path": "", "value": ["baz"]}]`,
+		want:  `["baz"]`,
+	},
+	{
+		name:  "move into one of its children",
+		doc:   `{"foo": {"bar": {}}}`,
+		patch: `[{"op": "move", "from": "/foo", "path": "/foo/bar/baz"}]`,
+	},
+	{
+		name:  "move to a sibling with a common prefix",
+		doc:   `{"foo": 1, "foobar": {}}`,
+		patch: `[{"op": "move", "from": "/foo", "path": "/foobar/foo"}]`,
+		want:  `{"foobar": {"foo": 1}}`,
+	},
+	{
+		name:  "move an array element to the front",
+		doc:   `{"foo": ["a", "b", "c", "d"]}`,
+		patch: `[{"op": "move", "from": "/foo/3", "path": "/foo/0"}]`,
+		want:  `{"foo": ["d", "a", "b", "c"]}`,
+	},
+	{
+		name:  "move an array element to the end",
+		doc:   `{"foo": ["a", "b", "c"]}`,
+		patch: `[{"op": "move", "from": "/foo/0", "path": "/foo/-"}]`,
+		want:  `{"foo": ["b", "c", "a"]}`,
+	},
+	{
+		name:  "move an array element into another array",
+		doc:   `{"foo": ["a", "b", "c"], "bar": ["x"]}`,
+		patch: `[{"op": "move", "from": "/foo/1", "path": "/bar/0"}]`,
+		want:  `{"foo": ["a", "c"], "bar": ["b", "x"]}`,
+	},
+	{
+		name:  "copy is independent of its source",
+		doc:   `{"foo": {"bar": 1}}`,
+		patch: `[{"op": "copy", "from": "/foo", "path": "/baz"}, {"op": "replace", "path": "/baz/bar", "value": 2}]`,
+		want:  `{"foo": {"bar": 1}, "baz": {"bar": 2}}`,
+	},
+	{
+		name:  "test compares numbers by value",
+		doc:   `{"port": 8080}`,
+		patch: `[{"op": "test", "path": "/port", "value": 8080.0}]`,
+		want:  `{"port": 8080}`,
+	},
+	{
+		name:  "unknown operation",
+		doc:   `{"foo": "bar"}`,
+		patch: `[{"op": "frobnicate", "path": "/foo"}]`,
+	},
+	{
+		name:  "missing value member",
+		doc:   `{"foo": "bar"}`,
+		patch: `[{"op": "add", "path": "/baz"}]`,
+	},
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	for _, tt := range jsonPatchTests {
+		t.Run(tt.name, func(t *testing.T) {
+			testApplyPatch(t, patchTypeJSON, tt.doc, tt.patch, tt.want)
+		})
+	}
+}
+
+// mergePatchTests are the examples of RFC 7386 appendix A
+var mergePatchTests = []struct {
+	doc   string
+	patch string
+	want  string
+}{
+	{`{"a": "b"}`, `{"a": "c"}`, `{"a": "c"}`},
+	{`{"a": "b"}`, `{"b": "c"}`, `{"a": "b", "b": "c"}`},
+	{`{"a": "b"}`, `{"a": null}`, `{}`},
+	{`{"a": "b", "b": "c"}`, `{"a": null}`, `{"b": "c"}`},
+	{`{"a": ["b"]}`, `{"a": "c"}`, `{"a": "c"}`},
+	{`{"a": "c"}`, `{"a": ["b"]}`, `{"a": ["b"]}`},
+	{`{"a": {"b": "c"}}`, `{"a": {"b": "d", "c": null}}`, `{"a": {"b": "d"}}`},
+	{`{"a": [{"b": "c"}]}`, `{"a": [1]}`, `{"a": [1]}`},
+	{`["a", "b"]`, `["c", "d"]`, `["c", "d"]`},
+	{`{"a": "b"}`, `["c"]`, `["c"]`},
+	{`{"a": "foo"}`, `null`, `null`},
+	{`{"a": "foo"}`, `"bar"`, `"bar"`},
+	{`{"e": null}`, `{"a": 1}`, `{"e": null, "a": 1}`},
+	{`[1, 2]`, `{"a": "b", "c": null}`, `{"a": "b"}`},
+	{`{}`, `{"a": {"bb": {"ccc": null}}}`, `{"a": {"bb": {}}}`},
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	for _, tt := range mergePatchTests {
+		t.Run(tt.patch, func(t *testing.T) {
+			testApplyPatch(t, patchTypeMerge, tt.doc, tt.patch, tt.want)
+		})
+	}
+}
+
+func testApplyPatch(t *testing.T, patchType, doc, patch, want string) {
+	t.Helper()
+
+	var data interface{}
+	if err := decodeJSON([]byte(doc), &data); err != nil {
+		t.Fatalf("invalid doc: %v", err)
+	}
+
+	got, err := applyPatch(data, patchType, patch)
+	if want == "" {
+		if err == nil {
+			t.Fatalf("applyPatch() = %s, want an error", compactJSON(got))
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("applyPatch() error = %v", err)
+	}
+
+	var expected interface{}
+	if err := decodeJSON([]byte(want), &expected); err != nil {
+		t.Fatalf("invalid want: %v", err)
+	}
+	if !jsonEqual(got, expected) {
+		t.Errorf("applyPatch() = %s, want %s", compactJSON(got), compactJSON(expected))
+	}
+}
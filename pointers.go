@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// marshalPointers formats decoded JSON as a flattened listing of
+// "jsonpointer = value" lines, one per scalar leaf
+func marshalPointers(data interface{}) (string, error) {
+	var sb strings.Builder
+	if err := writePointers(&sb, "", data); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
+
+// writePointers walks the decoded value and writes a line for every leaf.
+// Empty objects and arrays are emitted as leaves so they are not lost.
+func writePointers(sb *strings.Builder, pointer string, value interface{}) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			fmt.Fprintf(sb, "%s = {}\n", pointer)
+			return nil
+		}
+
+		// Sort keys so the output is stable for grepping and diffing
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if err := writePointers(sb, pointer+"/"+escapePointerToken(key), v[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			fmt.Fprintf(sb, "%s = []\n", pointer)
+			return nil
+		}
+
+		for i, item := range v {
+			if err := writePointers(sb, pointer+"/"+strconv.Itoa(i), item); err != nil {
+				return err
+			}
+		}
+	default:
+		// Scalars are rendered as JSON so strings stay distinguishable from numbers
+		fmt.Fprintf(sb, "%s = ", pointer)
+		enc := json.NewEncoder(sb)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("failed to format value at %q: %w", pointer, err)
+		}
+	}
+
+	return nil
+}
+
+// escapePointerToken escapes a reference token as described in RFC 6901
+func escapePointerToken(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"net"
 	"net/http"
@@ -12,6 +15,8 @@ import (
 	"os/exec"
 	"os/signal"
 	"runtime"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
@@ -26,8 +31,34 @@ type ConvertRequest struct {
 }
 
 type ConvertResponse struct {
-	YAML  string `json:"yaml,omitempty"`
-	Error string `json:"error,omitempty"`
+	YAML   string `json:"yaml,omitempty"`
+	Output string `json:"output,omitempty"` // result for formats other than yaml
+	Error  string `json:"error,omitempty"`
+}
+
+type FormatsResponse struct {
+	From []string `json:"from"`
+	To   []string `json:"to"`
+}
+
+// ServerOptions controls how the web server is run. The web command uses it
+// as a desktop GUI backend, the serve command as a long-lived API daemon.
+type ServerOptions struct {
+	Port      string
+	DevAssets string // directory whose files override the embedded web assets
+	// EnableFormats and DisableFormats restrict the from/to formats the
+	// conversion handlers accept. EnableFormats only restricts the inputs
+	// or outputs it names formats of; the other side stays unrestricted.
+	EnableFormats  []string
+	DisableFormats []string
+	OpenBrowser    bool // launch the GUI in the default browser on startup
+	ServeGUI       bool // serve the embedded HTML/CSS/JS in addition to the API
+	AutoShutdown   bool // exit once no client has been connected for 5 seconds
+	Heartbeat      bool // with AutoShutdown, also exit when the GUI stops sending /heartbeat
+	AccessLog      bool
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
 }
 
 var (
@@ -35,26 +66,68 @@ var (
 	shutdownTimer     *time.Timer
 	shutdownMutex     sync.Mutex
 	lastHeartbeat     int64
+	devAssets         fs.FS
+	ready             atomic.Bool
+	enabledInputs     []string
+	enabledOutputs    []string
+)
+
+// selfTestJSON and selfTestYAML are converted and compared on startup
+// before the server reports itself ready
+const (
+	selfTestJSON = `{"selftest": [1, "two", true, null]}`
+	selfTestYAML = "selftest:\n  - 1\n  - two\n  - true\n  - null\n"
 )
 
-func startWebServer(port string) error {
+func startWebServer(opts ServerOptions) error {
 	mux := http.NewServeMux()
 
+	if err := restrictFormats(opts.EnableFormats, opts.DisableFormats); err != nil {
+		return err
+	}
+
+	if opts.DevAssets != "" {
+		devAssets = os.DirFS(opts.DevAssets)
+		fmt.Printf("Serving web assets from %s (falling back to embedded files)\n", opts.DevAssets)
+	}
+
 	// Serve static files
-	mux.HandleFunc("/static/", handleStatic)
-	mux.HandleFunc("/", handleIndex)
+	if opts.ServeGUI {
+		mux.HandleFunc("/static/", handleStatic)
+		mux.HandleFunc("/", handleIndex)
+	}
 	mux.HandleFunc("/convert", handleConvert)
-	mux.HandleFunc("/heartbeat", handleHeartbeat)
+	mux.HandleFunc("/formats", handleFormats)
+	mux.HandleFunc("/livez", handleLivez)
+	mux.HandleFunc("/readyz", handleReadyz)
+	if opts.Heartbeat {
+		mux.HandleFunc("/heartbeat", handleHeartbeat)
+	}
+
+	var handler http.Handler = mux
+	if opts.AccessLog {
+		handler = accessLogHandler(handler)
+	}
 
-	addr := ":" + port
+	addr := ":" + opts.Port
 	fmt.Printf("Starting web server on http://localhost%s\n", addr)
-	fmt.Printf("Server will automatically shutdown when browser is closed\n")
+	if opts.Heartbeat {
+		fmt.Printf("Server will automatically shutdown when browser is closed\n")
+	} else if opts.AutoShutdown {
+		fmt.Printf("Server will automatically shutdown when no client is connected\n")
+	}
 
-	// Create HTTP server with connection tracking
 	server := &http.Server{
-		Addr:    addr,
-		Handler: mux,
-		ConnState: func(conn net.Conn, state http.ConnState) {
+		Addr:         addr,
+		Handler:      handler,
+		ReadTimeout:  opts.ReadTimeout,
+		WriteTimeout: opts.WriteTimeout,
+		IdleTimeout:  opts.IdleTimeout,
+	}
+
+	// Track connections so the server can exit once the browser is gone
+	if opts.AutoShutdown {
+		server.ConnState = func(conn net.Conn, state http.ConnState) {
 			switch state {
 			case http.StateNew:
 				activeConnections.Store(conn, true)
@@ -63,7 +136,13 @@ func startWebServer(port string) error {
 				activeConnections.Delete(conn)
 				scheduleShutdownIfNoConnections()
 			}
-		},
+		}
+
+		// API clients are not launched by the server, so also exit if
+		// none ever connects
+		if !opts.Heartbeat {
+			scheduleShutdownIfNoConnections()
+		}
 	}
 
 	// Handle graceful shutdown on signals
@@ -75,30 +154,123 @@ func startWebServer(port string) error {
 		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 		<-sigChan
 		fmt.Println("\nReceived shutdown signal...")
+		ready.Store(false)
 		cancel()
 		server.Shutdown(context.Background())
 	}()
 
 	// Launch browser after a short delay
-	go func() {
-		time.Sleep(500 * time.Millisecond)
-		openBrowser("http://localhost" + addr)
-	}()
+	if opts.OpenBrowser {
+		go func() {
+			time.Sleep(500 * time.Millisecond)
+			openBrowser("http://localhost" + addr)
+		}()
+	}
+
+	// Start shutdown monitoring. Only the GUI sends heartbeats; API
+	// clients are tracked by their connections alone.
+	if opts.AutoShutdown && opts.Heartbeat {
+		go monitorForAutoShutdown(ctx, server)
+	}
 
-	// Start shutdown monitoring
-	go monitorForAutoShutdown(ctx, server)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
 
-	err := server.ListenAndServe()
+	// The listener queues connections from here on, so the server is
+	// ready as soon as the conversion itself is known to work
+	if err := runSelfTest(); err != nil {
+		log.Printf("Self-test failed, server will not report ready: %v", err)
+	} else {
+		ready.Store(true)
+	}
+
+	err = server.Serve(listener)
 	if err == http.ErrServerClosed {
 		return nil
 	}
 	return err
 }
 
+// restrictFormats sets the formats the conversion handlers accept. Names
+// given to enable only restrict their own side, so enabling just an output
+// format keeps every input format allowed and vice versa.
+func restrictFormats(enable, disable []string) error {
+	for _, name := range slices.Concat(enable, disable) {
+		if !slices.Contains(inputFormats, name) && !slices.Contains(outputFormats, name) {
+			return fmt.Errorf("unknown format %q (supported: %s)", name, strings.Join(slices.Concat(inputFormats, outputFormats), ", "))
+		}
+	}
+
+	enabledInputs = allowedFormats(inputFormats, enable, disable)
+	if len(enabledInputs) == 0 {
+		return fmt.Errorf("no input format is enabled (input formats: %s)", strings.Join(inputFormats, ", "))
+	}
+	enabledOutputs = allowedFormats(outputFormats, enable, disable)
+	if len(enabledOutputs) == 0 {
+		return fmt.Errorf("no output format is enabled (output formats: %s)", strings.Join(outputFormats, ", "))
+	}
+
+	return nil
+}
+
+// allowedFormats returns the formats that are not disabled and, if enable
+// names any of formats, are enabled
+func allowedFormats(formats, enable, disable []string) []string {
+	restricted := slices.ContainsFunc(enable, func(name string) bool {
+		return slices.Contains(formats, name)
+	})
+
+	allowed := []string{}
+	for _, name := range formats {
+		if restricted && !slices.Contains(enable, name) {
+			continue
+		}
+		if !slices.Contains(disable, name) {
+			allowed = append(allowed, name)
+		}
+	}
+	return allowed
+}
+
+// runSelfTest converts a known document and checks the result
+func runSelfTest() error {
+	result, err := convertJSONToYAML(selfTestJSON)
+	if err != nil {
+		return err
+	}
+	if result != selfTestYAML {
+		return fmt.Errorf("unexpected conversion result %q", result)
+	}
+	return nil
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// accessLogHandler logs one line per request with its status and duration
+func accessLogHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		log.Printf("%s %s %s %d %s", r.RemoteAddr, r.Method, r.URL.Path, rec.status, time.Since(start))
+	})
+}
+
 func handleStatic(w http.ResponseWriter, r *http.Request) {
 	path := "web" + r.URL.Path[7:] // Remove "/static" prefix and add "web" prefix
 
-	data, err := webFS.ReadFile(path)
+	data, err := readWebAsset(path)
 	if err != nil {
 		http.NotFound(w, r)
 		return
@@ -120,7 +292,7 @@ func handleIndex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, err := webFS.ReadFile("web/index.html")
+	data, err := readWebAsset("web/index.html")
 	if err != nil {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
@@ -130,6 +302,31 @@ func handleIndex(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
+// readWebAsset reads a file under web/, preferring the --dev-assets
+// directory and falling back to the embedded copy so that only the files
+// being edited need to exist on disk
+func readWebAsset(name string) ([]byte, error) {
+	if devAssets != nil {
+		data, err := fs.ReadFile(devAssets, strings.TrimPrefix(name, "web/"))
+		if err == nil {
+			log.Printf("debug: served %s from dev assets", name)
+			return data, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("debug: failed to read %s from dev assets: %v", name, err)
+		}
+	}
+
+	data, err := webFS.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if devAssets != nil {
+		log.Printf("debug: served %s from embedded assets", name)
+	}
+	return data, nil
+}
+
 func handleConvert(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -150,15 +347,41 @@ func handleConvert(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Convert JSON to YAML using existing function
-	yamlResult, err := convertJSONToYAML(jsonContent)
+	from := cmp.Or(r.FormValue("from"), formatJSON)
+	if !slices.Contains(enabledInputs, from) {
+		sendErrorResponse(w, fmt.Sprintf("Input format %q is not enabled on this server", from), http.StatusBadRequest)
+		return
+	}
+
+	to := cmp.Or(r.FormValue("to"), formatYAML)
+	if !slices.Contains(enabledOutputs, to) {
+		sendErrorResponse(w, fmt.Sprintf("Output format %q is not enabled on this server", to), http.StatusBadRequest)
+		return
+	}
+
+	// Convert JSON using the same function as the CLI
+	result, err := convertJSONTo(jsonContent, ConvertOptions{From: from, Format: to})
 	if err != nil {
 		sendErrorResponse(w, fmt.Sprintf("Conversion failed: %v", err), http.StatusBadRequest)
 		return
 	}
 
-	response := ConvertResponse{
-		YAML: yamlResult,
+	var response ConvertResponse
+	if to == formatYAML {
+		response.YAML = result
+	} else {
+		response.Output = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleFormats lists the from/to formats this server accepts
+func handleFormats(w http.ResponseWriter, r *http.Request) {
+	response := FormatsResponse{
+		From: enabledInputs,
+		To:   enabledOutputs,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -175,6 +398,24 @@ func sendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// handleLivez reports that the process is up. Use it for liveness and
+// startup probes.
+func handleLivez(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// handleReadyz reports whether the server accepts conversions: the
+// self-test passed and it is not shutting down. Use it for readiness probes.
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if !ready.Load() {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
 func handleHeartbeat(w http.ResponseWriter, r *http.Request) {
 	atomic.StoreInt64(&lastHeartbeat, time.Now().Unix())
 	w.WriteHeader(http.StatusOK)